@@ -2,12 +2,19 @@ package widget
 
 import (
 	"image/color"
+	"time"
 
 	"fyne.io/fyne"
 	"fyne.io/fyne/canvas"
+	"fyne.io/fyne/driver/desktop"
+	"fyne.io/fyne/layout"
 	"fyne.io/fyne/theme"
 )
 
+// anchorPollInterval is how often a relative-positioned popUp checks whether its anchor has
+// moved, since fyne has no generic "object moved" notification to hook into instead.
+const anchorPollInterval = 100 * time.Millisecond
+
 // PopUp is a widget that can float above the user interface.
 // It wraps any standard elements with padding and a shadow.
 // If it is modal then the shadow will cover the entire canvas it hovers over and block interactions.
@@ -17,19 +24,141 @@ type PopUp struct {
 	Content fyne.CanvasObject
 	Canvas  fyne.Canvas
 
+	// OnDismiss, if set, is called from Hide whenever a shown popUp is hidden - including when the
+	// user taps outside a non-modal popUp or presses Escape on it. Pressing Escape on a modal
+	// popUp calls OnDismiss directly, without hiding the popUp.
+	OnDismiss func()
+
 	innerPos     fyne.Position
 	innerSize    fyne.Size
 	modal        bool
 	overlayShown bool
+	render       popUpContentRenderer
+
+	anchor         fyne.CanvasObject
+	anchorOffset   fyne.Position
+	anchorPollStop chan struct{}
+
+	// focusIndex is the index into focusableDescendants(Content) that currently holds focus,
+	// or -1 if none does. The popUp itself is always the one fyne.Canvas.Focus'd object while
+	// shown; TypedKey/TypedRune/FocusGained/FocusLost are forwarded to this descendant so that
+	// Tab/Shift-Tab can never hand focus to anything outside Content.
+	focusIndex int
+}
+
+// popUpContentRenderer is implemented by the popUp renderers to allow the content
+// object to be swapped after CreateRenderer has already run.
+type popUpContentRenderer interface {
+	applyContent()
 }
 
 // Hide this widget, if it was previously visible
 func (p *PopUp) Hide() {
-	if p.overlayShown {
-		p.Canvas.Overlays().Remove(p)
-		p.overlayShown = false
+	if !p.overlayShown {
+		return
+	}
+
+	p.stopAnchorTracking()
+
+	if f := p.focusedDescendant(); f != nil {
+		f.FocusLost()
 	}
+	p.focusIndex = -1
+
+	p.Canvas.Overlays().Remove(p)
+	p.overlayShown = false
 	p.BaseWidget.Hide()
+	if p.OnDismiss != nil {
+		p.OnDismiss()
+	}
+}
+
+// TypedKey implements fyne.Focusable. Escape hides non-modal popUps, which triggers OnDismiss
+// via Hide; for modal popUps, where whether to close is the caller's decision, it invokes
+// OnDismiss directly instead of hiding. Tab cycles focus forward through Content's focusable
+// descendants; all other keys are forwarded to whichever of those descendants is focused.
+func (p *PopUp) TypedKey(ev *fyne.KeyEvent) {
+	switch ev.Name {
+	case fyne.KeyEscape:
+		if p.modal {
+			if p.OnDismiss != nil {
+				p.OnDismiss()
+			}
+			return
+		}
+		p.Hide()
+	case fyne.KeyTab:
+		p.cycleFocus(false)
+	default:
+		if f := p.focusedDescendant(); f != nil {
+			f.TypedKey(ev)
+		}
+	}
+}
+
+// TypedRune implements fyne.Focusable, forwarding to whichever of Content's focusable
+// descendants is focused. PopUp has no text input of its own.
+func (p *PopUp) TypedRune(r rune) {
+	if f := p.focusedDescendant(); f != nil {
+		f.TypedRune(r)
+	}
+}
+
+// FocusGained implements fyne.Focusable, forwarding to whichever of Content's focusable
+// descendants is focused.
+func (p *PopUp) FocusGained() {
+	if f := p.focusedDescendant(); f != nil {
+		f.FocusGained()
+	}
+}
+
+// FocusLost implements fyne.Focusable, forwarding to whichever of Content's focusable
+// descendants is focused.
+func (p *PopUp) FocusLost() {
+	if f := p.focusedDescendant(); f != nil {
+		f.FocusLost()
+	}
+}
+
+// TypedShortcut implements fyne.Shortcutable. Shift+Tab cycles focus backward through
+// Content's focusable descendants; all other shortcuts are ignored.
+func (p *PopUp) TypedShortcut(shortcut fyne.Shortcut) {
+	if sc, ok := shortcut.(*desktop.CustomShortcut); ok && sc.KeyName == fyne.KeyTab && sc.Modifier == desktop.ShiftModifier {
+		p.cycleFocus(true)
+	}
+}
+
+// focusedDescendant returns the currently focused descendant of Content, or nil if none is.
+func (p *PopUp) focusedDescendant() fyne.Focusable {
+	focusables := focusableDescendants(p.Content)
+	if p.focusIndex < 0 || p.focusIndex >= len(focusables) {
+		return nil
+	}
+	return focusables[p.focusIndex]
+}
+
+// cycleFocus moves focus to the next (or, reversed, previous) focusable descendant of Content,
+// wrapping around. The popUp itself remains the canvas's focused object throughout, so Tab and
+// Shift-Tab can never hand focus to anything outside Content.
+func (p *PopUp) cycleFocus(reverse bool) {
+	focusables := focusableDescendants(p.Content)
+	if len(focusables) == 0 {
+		return
+	}
+
+	if f := p.focusedDescendant(); f != nil {
+		f.FocusLost()
+	}
+
+	switch {
+	case p.focusIndex < 0:
+		p.focusIndex = 0
+	case reverse:
+		p.focusIndex = (p.focusIndex - 1 + len(focusables)) % len(focusables)
+	default:
+		p.focusIndex = (p.focusIndex + 1) % len(focusables)
+	}
+	focusables[p.focusIndex].FocusGained()
 }
 
 // Move the widget to a new position. A PopUp position is absolute to the top, left of its canvas.
@@ -59,9 +188,131 @@ func (p *PopUp) Show() {
 		p.Canvas.Overlays().Add(p)
 		p.overlayShown = true
 	}
+
+	// The popUp itself takes canvas focus, whether modal or not, so that TypedKey actually
+	// receives Escape and - for Tab/Shift-Tab - cycleFocus can trap focus within Content.
+	p.focusIndex = -1
+	p.Canvas.Focus(p)
+	if focusables := focusableDescendants(p.Content); len(focusables) > 0 {
+		p.focusIndex = 0
+		focusables[0].FocusGained()
+	}
+
 	p.BaseWidget.Show()
 }
 
+// focusableDescendants returns every fyne.Focusable found by walking obj's container tree, in
+// depth-first order. Compound widgets that wrap focusable children internally rather than via
+// a *fyne.Container (anything not itself Focusable, but whose renderer exposes one) are walked
+// through their renderer's Objects, so they don't need to be Container-wrapped to participate
+// in Tab/Shift-Tab cycling.
+func focusableDescendants(obj fyne.CanvasObject) []fyne.Focusable {
+	var found []fyne.Focusable
+	if f, ok := obj.(fyne.Focusable); ok {
+		found = append(found, f)
+	}
+
+	switch o := obj.(type) {
+	case *fyne.Container:
+		for _, child := range o.Objects {
+			found = append(found, focusableDescendants(child)...)
+		}
+	case fyne.Widget:
+		if _, ok := obj.(fyne.Focusable); ok {
+			break
+		}
+		for _, child := range o.CreateRenderer().Objects() {
+			found = append(found, focusableDescendants(child)...)
+		}
+	}
+	return found
+}
+
+// ShowAtRelativePosition shows this popUp positioned at offset from the top-left of relative,
+// rather than at an absolute canvas position. The popUp's position is recomputed relative to
+// relative on every layout (Show, Resize/Refresh from a canvas resize) and, since fyne has no
+// generic notification for an arbitrary object moving, polled every anchorPollInterval for as
+// long as the popUp is shown - so it follows relative even when relative is repositioned by
+// its own parent (a scroll, a relayout) rather than by a canvas resize.
+func (p *PopUp) ShowAtRelativePosition(relative fyne.CanvasObject, offset fyne.Position) {
+	p.anchor = relative
+	p.anchorOffset = offset
+	p.Refresh()
+	p.Show()
+	p.startAnchorTracking()
+}
+
+// startAnchorTracking polls the anchor's absolute position at anchorPollInterval and
+// Refreshes the popUp whenever it has moved. It replaces any tracking already in progress.
+func (p *PopUp) startAnchorTracking() {
+	p.stopAnchorTracking()
+	stop := make(chan struct{})
+	p.anchorPollStop = stop
+
+	anchor := p.anchor
+	go func() {
+		ticker := time.NewTicker(anchorPollInterval)
+		defer ticker.Stop()
+
+		last := fyne.CurrentApp().Driver().AbsolutePositionForObject(anchor)
+		for {
+			select {
+			case <-stop:
+				return
+			case <-ticker.C:
+				if pos := fyne.CurrentApp().Driver().AbsolutePositionForObject(anchor); pos != last {
+					last = pos
+					p.Refresh()
+				}
+			}
+		}
+	}()
+}
+
+// stopAnchorTracking stops any anchor-position polling started by startAnchorTracking.
+func (p *PopUp) stopAnchorTracking() {
+	if p.anchorPollStop == nil {
+		return
+	}
+	close(p.anchorPollStop)
+	p.anchorPollStop = nil
+}
+
+// positionForAnchor computes the canvas-absolute position at which the popUp should be drawn
+// given its anchor and offset, flipping to the opposite side of the anchor when there isn't
+// room below or to the right, rather than letting it clip at the canvas edge.
+func (p *PopUp) positionForAnchor() fyne.Position {
+	anchorPos := fyne.CurrentApp().Driver().AbsolutePositionForObject(p.anchor)
+	anchorSize := p.anchor.Size()
+	canvasSize := p.Canvas.Size()
+
+	pos := anchorPos.Add(p.anchorOffset)
+	if pos.X+p.innerSize.Width > canvasSize.Width {
+		if flipped := anchorPos.X + anchorSize.Width - p.anchorOffset.X - p.innerSize.Width; flipped >= 0 {
+			pos.X = flipped
+		}
+	}
+	if pos.Y+p.innerSize.Height > canvasSize.Height {
+		if flipped := anchorPos.Y + anchorSize.Height - p.anchorOffset.Y - p.innerSize.Height; flipped >= 0 {
+			pos.Y = flipped
+		}
+	}
+	return pos
+}
+
+// SetContent swaps the content shown in the popUp, replacing whatever was shown before,
+// and triggers a layout and refresh so the change is reflected immediately.
+func (p *PopUp) SetContent(obj fyne.CanvasObject) {
+	p.Content = obj
+
+	if p.render != nil {
+		p.render.applyContent()
+	}
+
+	p.Resize(p.MinSize())
+	p.Refresh()
+}
+
 // Tapped is called when the user taps the popUp background - if not modal then dismiss this widget
 func (p *PopUp) Tapped(_ *fyne.PointEvent) {
 	if !p.modal {
@@ -86,14 +337,16 @@ func (p *PopUp) MinSize() fyne.Size {
 func (p *PopUp) CreateRenderer() fyne.WidgetRenderer {
 	p.ExtendBaseWidget(p)
 	if p.modal {
-		bg := canvas.NewRectangle(theme.BackgroundColor())
-		return &modalPopUpRenderer{popUp: p, bg: bg, objects: []fyne.CanvasObject{bg, p.Content}}
+		r := &modalPopUpRenderer{popUp: p, bg: canvas.NewRectangle(theme.BackgroundColor())}
+		r.applyContent()
+		p.render = r
+		return r
 	}
 
-	shadow := newShadow(shadowAround, theme.Padding()*2)
-	bg := canvas.NewRectangle(theme.BackgroundColor())
-	objects := []fyne.CanvasObject{shadow, bg, p.Content}
-	return &popUpRenderer{popUp: p, shadow: shadow, bg: bg, objects: objects}
+	r := &popUpRenderer{popUp: p, shadow: newShadow(shadowAround, theme.Padding()*2), bg: canvas.NewRectangle(theme.BackgroundColor())}
+	r.applyContent()
+	p.render = r
+	return r
 }
 
 // NewPopUpAtPosition creates a new popUp for the specified content at the specified absolute position.
@@ -113,6 +366,18 @@ func NewPopUp(content fyne.CanvasObject, canvas fyne.Canvas) *PopUp {
 	return NewPopUpAtPosition(content, canvas, fyne.NewPos(0, 0))
 }
 
+// NewPopUpAtRelativePosition creates a new popUp for the specified content, anchored at offset
+// from relative, and displays it on the passed canvas. Unlike NewPopUpAtPosition, the popUp
+// tracks relative's position and the canvas size, so callers don't need to compute absolute
+// coordinates themselves.
+func NewPopUpAtRelativePosition(content fyne.CanvasObject, canvas fyne.Canvas, relative fyne.CanvasObject, offset fyne.Position) *PopUp {
+	ret := &PopUp{Content: content, Canvas: canvas, modal: false}
+	ret.ExtendBaseWidget(ret)
+	ret.Resize(ret.MinSize())
+	ret.ShowAtRelativePosition(relative, offset)
+	return ret
+}
+
 // NewModalPopUp creates a new popUp for the specified content and displays it on the passed canvas.
 // A modal PopUp blocks interactions with underlying elements, covered with a semi-transparent overlay.
 func NewModalPopUp(content fyne.CanvasObject, canvas fyne.Canvas) *PopUp {
@@ -123,6 +388,20 @@ func NewModalPopUp(content fyne.CanvasObject, canvas fyne.Canvas) *PopUp {
 	return ret
 }
 
+// ShowLoadingPopUp creates and shows a modal popUp containing the given message above an
+// indeterminate progress bar. It is intended to be shown for the duration of a long-running
+// task and dismissed with Hide once that task completes, e.g.:
+//
+//	popup := widget.ShowLoadingPopUp(canvas, "Loading...")
+//	defer popup.Hide()
+func ShowLoadingPopUp(canvas fyne.Canvas, message string) *PopUp {
+	content := fyne.NewContainerWithLayout(layout.NewVBoxLayout(),
+		NewLabel(message),
+		NewProgressBarInfinite())
+
+	return NewModalPopUp(content, canvas)
+}
+
 type popUpRenderer struct {
 	popUp   *PopUp
 	shadow  fyne.CanvasObject
@@ -135,6 +414,9 @@ func (r *popUpRenderer) Layout(_ fyne.Size) {
 	r.popUp.Content.Resize(contentSize)
 
 	innerPos := r.popUp.innerPos
+	if r.popUp.anchor != nil {
+		innerPos = r.popUp.positionForAnchor()
+	}
 	if innerPos.X+r.popUp.innerSize.Width > r.popUp.Canvas.Size().Width {
 		innerPos.X = r.popUp.Canvas.Size().Width - r.popUp.innerSize.Width
 		if innerPos.X < 0 {
@@ -167,6 +449,13 @@ func (r *popUpRenderer) Refresh() {
 	}
 }
 
+// applyContent rebuilds the object slice so a new Content is actually drawn,
+// then re-runs layout so it is sized and positioned correctly.
+func (r *popUpRenderer) applyContent() {
+	r.objects = []fyne.CanvasObject{r.shadow, r.bg, r.popUp.Content}
+	r.Layout(r.popUp.Size())
+}
+
 func (r *popUpRenderer) BackgroundColor() color.Color {
 	return color.Transparent
 }
@@ -207,6 +496,13 @@ func (r *modalPopUpRenderer) Refresh() {
 	}
 }
 
+// applyContent rebuilds the object slice so a new Content is actually drawn,
+// then re-runs layout so it is sized and positioned correctly.
+func (r *modalPopUpRenderer) applyContent() {
+	r.objects = []fyne.CanvasObject{r.bg, r.popUp.Content}
+	r.Layout(r.popUp.Canvas.Size())
+}
+
 func (r *modalPopUpRenderer) BackgroundColor() color.Color {
 	return theme.ShadowColor()
 }